@@ -0,0 +1,159 @@
+package cache
+
+import (
+	"bytes"
+	"container/list"
+	"encoding/gob"
+	"fmt"
+	"sync"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var bucketName = []byte("resources")
+
+// BoltStore persists cached entries to a single file on disk via BoltDB, so
+// the cache survives process restarts, with the same LRU byte-budget
+// eviction as MemoryStore so --cache-size has an effect here too.
+type BoltStore struct {
+	db *bolt.DB
+
+	mu       sync.Mutex
+	maxBytes int64
+	curBytes int64
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type boltIndexEntry struct {
+	key  string
+	size int64
+}
+
+// NewBoltStore opens (creating if needed) a BoltDB database at path.
+// maxBytes bounds the total gob-encoded size of cached entries (body plus
+// headers) before the least-recently-used entry is evicted; zero means no
+// limit.
+func NewBoltStore(path string, maxBytes int64) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		return nil, fmt.Errorf("opening bolt cache at %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(bucketName)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	b := &BoltStore{
+		db:       db,
+		maxBytes: maxBytes,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+	if err := b.loadIndex(); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return b, nil
+}
+
+// loadIndex seeds the in-memory LRU index from whatever's already on disk,
+// so a restarted process still enforces its byte budget against entries
+// from a previous run instead of only tracking usage from the next write.
+// Bolt doesn't record access times, so the seeded order is bucket iteration
+// order rather than true recency -- it self-corrects as entries are read
+// and written again.
+func (b *BoltStore) loadIndex() error {
+	return b.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketName).ForEach(func(key, raw []byte) error {
+			k := string(key)
+			el := b.ll.PushBack(&boltIndexEntry{key: k, size: int64(len(raw))})
+			b.items[k] = el
+			b.curBytes += int64(len(raw))
+			return nil
+		})
+	})
+}
+
+func (b *BoltStore) Get(key string) (*Entry, bool) {
+	var entry Entry
+	found := false
+	_ = b.db.View(func(tx *bolt.Tx) error {
+		raw := tx.Bucket(bucketName).Get([]byte(key))
+		if raw == nil {
+			return nil
+		}
+		if err := gob.NewDecoder(bytes.NewReader(raw)).Decode(&entry); err != nil {
+			return err
+		}
+		found = true
+		return nil
+	})
+	if !found {
+		return nil, false
+	}
+
+	b.mu.Lock()
+	if el, ok := b.items[key]; ok {
+		b.ll.MoveToFront(el)
+	}
+	b.mu.Unlock()
+
+	return &entry, true
+}
+
+func (b *BoltStore) Set(key string, entry *Entry) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(entry); err != nil {
+		return err
+	}
+	size := int64(buf.Len())
+
+	if err := b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketName).Put([]byte(key), buf.Bytes())
+	}); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if el, ok := b.items[key]; ok {
+		b.curBytes -= el.Value.(*boltIndexEntry).size
+		el.Value = &boltIndexEntry{key: key, size: size}
+		b.ll.MoveToFront(el)
+	} else {
+		el := b.ll.PushFront(&boltIndexEntry{key: key, size: size})
+		b.items[key] = el
+	}
+	b.curBytes += size
+
+	for b.maxBytes > 0 && b.curBytes > b.maxBytes && b.ll.Len() > 0 {
+		b.evictOldest()
+	}
+	return nil
+}
+
+// evictOldest drops the least-recently-used entry from both the on-disk
+// bucket and the in-memory LRU index. Called with b.mu held.
+func (b *BoltStore) evictOldest() {
+	el := b.ll.Back()
+	if el == nil {
+		return
+	}
+	ie := el.Value.(*boltIndexEntry)
+	_ = b.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketName).Delete([]byte(ie.key))
+	})
+	b.ll.Remove(el)
+	delete(b.items, ie.key)
+	b.curBytes -= ie.size
+}
+
+func (b *BoltStore) Close() error {
+	return b.db.Close()
+}
@@ -0,0 +1,45 @@
+// Package cache provides an on-disk resource cache with ETag/Last-Modified
+// revalidation, so a warm page reload doesn't re-fetch the origin HTML and
+// every linked stylesheet/script on every request.
+package cache
+
+import (
+	"time"
+)
+
+// Entry is one cached HTTP resource.
+type Entry struct {
+	Body        []byte
+	ContentType string
+	// StatusCode is the origin's HTTP status for this entry. A non-2xx
+	// entry is still cached and returned like any other -- it's up to the
+	// caller (fetchResource's CSS/JS retry path) to decide whether a given
+	// status is worth retrying; the page-level "/" and "/reader" handlers
+	// just render whatever the origin sent, custom error pages included.
+	StatusCode   int
+	ETag         string
+	LastModified string
+	// Expiry is when this entry should be treated as stale and
+	// revalidated against the origin. Zero means "always revalidate".
+	Expiry time.Time
+}
+
+// Fresh reports whether the entry can be served without revalidation.
+func (e *Entry) Fresh() bool {
+	return !e.Expiry.IsZero() && time.Now().Before(e.Expiry)
+}
+
+// Store is the pluggable backend a Cache is built on: an in-memory LRU for
+// single-process deployments, or a BoltDB-backed store that survives
+// restarts.
+type Store interface {
+	Get(key string) (*Entry, bool)
+	Set(key string, entry *Entry) error
+	Close() error
+}
+
+// Stats are the hit/miss counters exposed through DebugStats.
+type Stats struct {
+	Hits   int64
+	Misses int64
+}
@@ -0,0 +1,174 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// StatusError reports a non-2xx, non-304 response from the origin. Cache
+// itself doesn't return this -- a non-2xx response is cached and returned
+// as a normal *Entry, same as a 2xx, since the page-level handlers need the
+// origin's actual error-page body. It's for callers that only want 2xx,
+// like main.go's fetchResource, to construct when deciding whether a CSS/JS
+// fetch is worth retrying.
+type StatusError struct {
+	URL        string
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s: unexpected status %d", e.URL, e.StatusCode)
+}
+
+// Cache wraps a Store with HTTP semantics: Cache-Control-aware TTLs,
+// If-None-Match/If-Modified-Since revalidation, and singleflight
+// coalescing so concurrent requests for the same URL only hit the origin
+// once.
+type Cache struct {
+	store    Store
+	client   *http.Client
+	ttlCap   time.Duration
+	decorate func(*http.Request)
+	group    singleflight.Group
+	hits     int64
+	misses   int64
+}
+
+// New returns a Cache backed by store. ttlCap bounds how long an entry can
+// be considered fresh even if the origin's Cache-Control asks for longer;
+// zero means no cap. decorate, if non-nil, is applied to every outgoing
+// request before it's sent (e.g. to set the User-Agent/Accept headers the
+// rest of the proxy uses); it may be nil.
+func New(store Store, client *http.Client, ttlCap time.Duration, decorate func(*http.Request)) *Cache {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return &Cache{store: store, client: client, ttlCap: ttlCap, decorate: decorate}
+}
+
+// Stats returns a snapshot of the cache's hit/miss counters for
+// DebugStats.
+func (c *Cache) Stats() Stats {
+	return Stats{Hits: atomic.LoadInt64(&c.hits), Misses: atomic.LoadInt64(&c.misses)}
+}
+
+// Fetch returns the body and content type for url, serving from cache when
+// fresh, revalidating with the origin when stale, and coalescing
+// concurrent fetches for the same url via singleflight.
+func (c *Cache) Fetch(url string) (*Entry, error) {
+	return c.FetchContext(context.Background(), url)
+}
+
+// FetchContext is Fetch with a caller-supplied context, so a fetch can
+// carry its own deadline distinct from (and typically shorter than) the
+// overall page-request timeout.
+func (c *Cache) FetchContext(ctx context.Context, url string) (*Entry, error) {
+	if entry, ok := c.store.Get(url); ok && entry.Fresh() {
+		atomic.AddInt64(&c.hits, 1)
+		return entry, nil
+	}
+
+	v, err, _ := c.group.Do(url, func() (interface{}, error) {
+		return c.fetchAndStore(ctx, url)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*Entry), nil
+}
+
+func (c *Cache) fetchAndStore(ctx context.Context, url string) (*Entry, error) {
+	cached, hadCached := c.store.Get(url)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.decorate != nil {
+		c.decorate(req)
+	}
+	if hadCached {
+		if cached.ETag != "" {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			req.Header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		atomic.AddInt64(&c.misses, 1)
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && hadCached {
+		atomic.AddInt64(&c.hits, 1)
+		cached.Expiry = c.expiryFor(resp.Header)
+		_ = c.store.Set(url, cached)
+		return cached, nil
+	}
+
+	atomic.AddInt64(&c.misses, 1)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &Entry{
+		Body:         body,
+		ContentType:  resp.Header.Get("Content-Type"),
+		StatusCode:   resp.StatusCode,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		Expiry:       c.expiryFor(resp.Header),
+	}
+	_ = c.store.Set(url, entry)
+	return entry, nil
+}
+
+// expiryFor computes a freshness deadline from the response's Cache-Control
+// max-age, capped by ttlCap when set. The Expires header is not consulted.
+func (c *Cache) expiryFor(header http.Header) time.Time {
+	ttl := parseMaxAge(header.Get("Cache-Control"))
+	if ttl == 0 {
+		// No explicit freshness lifetime: treat as immediately stale so the
+		// next request revalidates with If-None-Match rather than serving
+		// indefinitely.
+		return time.Time{}
+	}
+	if c.ttlCap > 0 && ttl > c.ttlCap {
+		ttl = c.ttlCap
+	}
+	return time.Now().Add(ttl)
+}
+
+func parseMaxAge(cacheControl string) time.Duration {
+	if cacheControl == "" {
+		return 0
+	}
+	for _, directive := range strings.Split(cacheControl, ",") {
+		directive = strings.TrimSpace(directive)
+		if strings.HasPrefix(directive, "no-store") || strings.HasPrefix(directive, "no-cache") {
+			return 0
+		}
+		if strings.HasPrefix(directive, "max-age=") {
+			seconds, err := strconv.Atoi(strings.TrimPrefix(directive, "max-age="))
+			if err != nil || seconds <= 0 {
+				return 0
+			}
+			return time.Duration(seconds) * time.Second
+		}
+	}
+	return 0
+}
@@ -0,0 +1,77 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+)
+
+// MemoryStore is an in-process LRU Store with a byte-size budget. It's the
+// default backend and requires no extra configuration, at the cost of the
+// cache being cold again after every restart.
+type MemoryStore struct {
+	mu       sync.Mutex
+	maxBytes int64
+	curBytes int64
+	ll       *list.List
+	items    map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key   string
+	entry *Entry
+}
+
+// NewMemoryStore returns a MemoryStore that evicts least-recently-used
+// entries once the cached bodies exceed maxBytes total.
+func NewMemoryStore(maxBytes int64) *MemoryStore {
+	return &MemoryStore{
+		maxBytes: maxBytes,
+		ll:       list.New(),
+		items:    make(map[string]*list.Element),
+	}
+}
+
+func (m *MemoryStore) Get(key string) (*Entry, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	el, ok := m.items[key]
+	if !ok {
+		return nil, false
+	}
+	m.ll.MoveToFront(el)
+	return el.Value.(*memoryEntry).entry, true
+}
+
+func (m *MemoryStore) Set(key string, entry *Entry) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if el, ok := m.items[key]; ok {
+		m.curBytes -= int64(len(el.Value.(*memoryEntry).entry.Body))
+		el.Value = &memoryEntry{key: key, entry: entry}
+		m.ll.MoveToFront(el)
+	} else {
+		el := m.ll.PushFront(&memoryEntry{key: key, entry: entry})
+		m.items[key] = el
+	}
+	m.curBytes += int64(len(entry.Body))
+
+	for m.maxBytes > 0 && m.curBytes > m.maxBytes && m.ll.Len() > 0 {
+		m.evictOldest()
+	}
+	return nil
+}
+
+func (m *MemoryStore) evictOldest() {
+	el := m.ll.Back()
+	if el == nil {
+		return
+	}
+	m.ll.Remove(el)
+	me := el.Value.(*memoryEntry)
+	delete(m.items, me.key)
+	m.curBytes -= int64(len(me.entry.Body))
+}
+
+func (m *MemoryStore) Close() error { return nil }
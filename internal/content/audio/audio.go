@@ -0,0 +1,66 @@
+// Package audio handles audio/* responses: wrap the stream in a minimal
+// HTML5 <audio> player when the request didn't ask for the raw bytes (i.e.
+// the browser navigated here directly rather than requesting the media the
+// player found in a page). Byte-range requests never reach this handler —
+// main.go's proxyRange intercepts them before the cache/content dispatch so
+// they can be proxied straight to the origin without being buffered.
+package audio
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Halffd/nav/internal/content"
+)
+
+func init() {
+	content.Register(handler{})
+}
+
+type handler struct{}
+
+func (handler) CanHandle(contentType, url string) bool {
+	return strings.HasPrefix(contentType, "audio/")
+}
+
+func (handler) Serve(c *fiber.Ctx, resp *http.Response, url string) error {
+	defer resp.Body.Close()
+
+	if c.Query("raw") == "1" {
+		return passthrough(c, resp)
+	}
+
+	c.Set("Content-Type", "text/html; charset=utf-8")
+	return c.SendString(playerHTML(c.OriginalURL()))
+}
+
+// passthrough streams the cached entry's body through unchanged for a
+// direct ?raw=1 request. It's not range-aware — actual seeking is handled
+// upstream by main.go's proxyRange before the request ever reaches here.
+func passthrough(c *fiber.Ctx, resp *http.Response) error {
+	c.Status(resp.StatusCode)
+	for _, header := range []string{"Content-Type", "Content-Length", "Content-Range", "Accept-Ranges", "Cache-Control"} {
+		if v := resp.Header.Get(header); v != "" {
+			c.Set(header, v)
+		}
+	}
+	return c.SendStream(resp.Body)
+}
+
+func playerHTML(rawSrc string) string {
+	src := rawSrc
+	if !strings.Contains(src, "raw=1") {
+		sep := "&"
+		if !strings.Contains(src, "?") {
+			sep = "?"
+		}
+		src = src + sep + "raw=1"
+	}
+	return fmt.Sprintf(`<!DOCTYPE html><html><head><meta charset="utf-8"><title>Audio</title></head>
+<body style="margin:0;display:flex;align-items:center;justify-content:center;height:100vh;background:#111">
+<audio controls autoplay style="width:80%%" src=%q></audio>
+</body></html>`, src)
+}
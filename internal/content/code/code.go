@@ -0,0 +1,60 @@
+// Package code renders text/* responses that look like source code as a
+// syntax-highlighted page using chroma, instead of dumping raw text or
+// (worse) letting the browser try to render it as HTML.
+package code
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Halffd/nav/internal/content"
+)
+
+func init() {
+	content.Register(handler{})
+}
+
+type handler struct{}
+
+// CanHandle claims a text/* response only if chroma recognizes the URL's
+// extension as belonging to some language lexer, so that plain prose
+// (text/plain, text/markdown without a code extension, etc.) still falls
+// through to the plain-text handler.
+func (handler) CanHandle(contentType, url string) bool {
+	if !strings.HasPrefix(contentType, "text/") || strings.HasPrefix(contentType, "text/html") {
+		return false
+	}
+	return lexers.Match(url) != nil
+}
+
+func (handler) Serve(c *fiber.Ctx, resp *http.Response, url string) error {
+	defer resp.Body.Close()
+
+	source, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	lexer := lexers.Match(url)
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+
+	iterator, err := lexer.Tokenise(nil, string(source))
+	if err != nil {
+		c.Set("Content-Type", "text/plain; charset=utf-8")
+		return c.Send(source)
+	}
+
+	formatter := html.New(html.WithLineNumbers(true), html.Standalone(true))
+	style := styles.Get("monokai")
+
+	c.Set("Content-Type", "text/html; charset=utf-8")
+	return formatter.Format(c.Response().BodyWriter(), style, iterator)
+}
@@ -0,0 +1,46 @@
+// Package content dispatches a fetched upstream response to whichever
+// registered Handler knows how to render that MIME family, instead of
+// forcing every response through the HTML rewriting pipeline.
+package content
+
+import (
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Handler renders one family of content type directly to the client.
+// Handlers register themselves with Register, typically from an init()
+// in their own package, so main only needs to import each handler
+// package for its side effect.
+type Handler interface {
+	// CanHandle reports whether this handler should serve a response with
+	// the given Content-Type and request URL.
+	CanHandle(contentType, url string) bool
+	// Serve streams resp to c, taking ownership of resp.Body. url is the
+	// origin resource URL CanHandle matched against, which is not
+	// necessarily c.OriginalURL() (that's this proxy's own /?url=...&sig=...
+	// request path).
+	Serve(c *fiber.Ctx, resp *http.Response, url string) error
+}
+
+var registry []Handler
+
+// Register adds h to the set of handlers consulted by Lookup. Handlers are
+// tried in registration order, so more specific handlers should register
+// before more general ones.
+func Register(h Handler) {
+	registry = append(registry, h)
+}
+
+// Lookup returns the first registered handler willing to serve
+// contentType/url, or nil if none claims it and the caller should fall
+// back to the default HTML pipeline.
+func Lookup(contentType, url string) Handler {
+	for _, h := range registry {
+		if h.CanHandle(contentType, url) {
+			return h
+		}
+	}
+	return nil
+}
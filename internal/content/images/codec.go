@@ -0,0 +1,16 @@
+package images
+
+import (
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+)
+
+func pngEncoder(w io.Writer, img image.Image) error {
+	return png.Encode(w, img)
+}
+
+func jpegEncoder(w io.Writer, img image.Image) error {
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: 85})
+}
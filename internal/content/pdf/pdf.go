@@ -0,0 +1,52 @@
+// Package pdf handles application/pdf responses by wrapping the raw bytes
+// in a page that embeds them via the browser's native PDF viewer.
+package pdf
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Halffd/nav/internal/content"
+)
+
+func init() {
+	content.Register(handler{})
+}
+
+type handler struct{}
+
+func (handler) CanHandle(contentType, url string) bool {
+	return contentType == "application/pdf"
+}
+
+func (handler) Serve(c *fiber.Ctx, resp *http.Response, url string) error {
+	defer resp.Body.Close()
+
+	if c.Query("raw") == "1" {
+		c.Set("Content-Type", "application/pdf")
+		return c.SendStream(resp.Body)
+	}
+
+	rawURL := c.OriginalURL()
+	sep := "&"
+	if len(rawURL) == 0 || !containsQuery(rawURL) {
+		sep = "?"
+	}
+
+	c.Set("Content-Type", "text/html; charset=utf-8")
+	return c.SendString(fmt.Sprintf(`<!DOCTYPE html><html><head><meta charset="utf-8"><title>PDF</title></head>
+<body style="margin:0">
+<embed src=%q type="application/pdf" style="width:100%%;height:100vh" />
+</body></html>`, rawURL+sep+"raw=1"))
+}
+
+func containsQuery(url string) bool {
+	for i := 0; i < len(url); i++ {
+		if url[i] == '?' {
+			return true
+		}
+	}
+	return false
+}
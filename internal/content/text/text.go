@@ -0,0 +1,29 @@
+// Package text is the catch-all handler for text/* responses that the
+// code package didn't claim as source: plain text, CSV, and similar. It
+// streams the body through verbatim with its original content type.
+package text
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Halffd/nav/internal/content"
+)
+
+func init() {
+	content.Register(handler{})
+}
+
+type handler struct{}
+
+func (handler) CanHandle(contentType, url string) bool {
+	return strings.HasPrefix(contentType, "text/") && !strings.HasPrefix(contentType, "text/html")
+}
+
+func (handler) Serve(c *fiber.Ctx, resp *http.Response, url string) error {
+	defer resp.Body.Close()
+	c.Set("Content-Type", resp.Header.Get("Content-Type"))
+	return c.SendStream(resp.Body)
+}
@@ -0,0 +1,66 @@
+// Package video handles video/* responses the same way audio does: a
+// direct navigation gets a minimal HTML5 <video> player wrapper, and
+// ?raw=1 streams the cached body through unchanged. Byte-range requests
+// never reach this handler — main.go's proxyRange intercepts them before
+// the cache/content dispatch so native seeking is proxied straight to the
+// origin instead of being served out of a fully-buffered cache entry.
+package video
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/Halffd/nav/internal/content"
+)
+
+func init() {
+	content.Register(handler{})
+}
+
+type handler struct{}
+
+func (handler) CanHandle(contentType, url string) bool {
+	return strings.HasPrefix(contentType, "video/")
+}
+
+func (handler) Serve(c *fiber.Ctx, resp *http.Response, url string) error {
+	defer resp.Body.Close()
+
+	if c.Query("raw") == "1" {
+		return passthrough(c, resp)
+	}
+
+	c.Set("Content-Type", "text/html; charset=utf-8")
+	return c.SendString(playerHTML(c.OriginalURL()))
+}
+
+// passthrough streams the cached entry's body through unchanged for a
+// direct ?raw=1 request. It's not range-aware — actual seeking is handled
+// upstream by main.go's proxyRange before the request ever reaches here.
+func passthrough(c *fiber.Ctx, resp *http.Response) error {
+	c.Status(resp.StatusCode)
+	for _, header := range []string{"Content-Type", "Content-Length", "Content-Range", "Accept-Ranges", "Cache-Control"} {
+		if v := resp.Header.Get(header); v != "" {
+			c.Set(header, v)
+		}
+	}
+	return c.SendStream(resp.Body)
+}
+
+func playerHTML(rawSrc string) string {
+	src := rawSrc
+	if !strings.Contains(src, "raw=1") {
+		sep := "&"
+		if !strings.Contains(src, "?") {
+			sep = "?"
+		}
+		src = src + sep + "raw=1"
+	}
+	return fmt.Sprintf(`<!DOCTYPE html><html><head><meta charset="utf-8"><title>Video</title></head>
+<body style="margin:0;background:#000">
+<video controls autoplay style="width:100%%;height:100vh" src=%q></video>
+</body></html>`, src)
+}
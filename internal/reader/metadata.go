@@ -0,0 +1,88 @@
+package reader
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+type pageMetadata struct {
+	Title       string
+	Byline      string
+	PublishDate string
+}
+
+// extractMetadata reads title/byline/publish date preferring OpenGraph
+// meta tags and JSON-LD, falling back to <title> and a generic byline
+// selector.
+func extractMetadata(doc *goquery.Document) pageMetadata {
+	meta := pageMetadata{}
+
+	meta.Title = firstNonEmpty(
+		metaContent(doc, "property", "og:title"),
+	)
+
+	meta.Byline = firstNonEmpty(
+		metaContent(doc, "name", "author"),
+		metaContent(doc, "property", "article:author"),
+	)
+
+	meta.PublishDate = firstNonEmpty(
+		metaContent(doc, "property", "article:published_time"),
+		metaContent(doc, "name", "date"),
+	)
+
+	applyJSONLD(doc, &meta)
+
+	meta.Title = firstNonEmpty(meta.Title, doc.Find("title").First().Text())
+	meta.Byline = firstNonEmpty(meta.Byline, doc.Find("[rel='author'], .byline, .author").First().Text())
+
+	meta.Title = strings.TrimSpace(meta.Title)
+	meta.Byline = strings.TrimSpace(meta.Byline)
+	meta.PublishDate = strings.TrimSpace(meta.PublishDate)
+	return meta
+}
+
+func metaContent(doc *goquery.Document, attr, value string) string {
+	content, _ := doc.Find("meta[" + attr + "='" + value + "']").Attr("content")
+	return content
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// jsonLDArticle is the subset of schema.org Article fields we read when
+// OpenGraph tags are missing.
+type jsonLDArticle struct {
+	Headline      string `json:"headline"`
+	DatePublished string `json:"datePublished"`
+	Author        struct {
+		Name string `json:"name"`
+	} `json:"author"`
+}
+
+func applyJSONLD(doc *goquery.Document, meta *pageMetadata) {
+	doc.Find(`script[type="application/ld+json"]`).EachWithBreak(func(i int, s *goquery.Selection) bool {
+		var article jsonLDArticle
+		if err := json.Unmarshal([]byte(s.Text()), &article); err != nil {
+			return true // keep looking at the next script block
+		}
+		if meta.Title == "" {
+			meta.Title = article.Headline
+		}
+		if meta.Byline == "" {
+			meta.Byline = article.Author.Name
+		}
+		if meta.PublishDate == "" {
+			meta.PublishDate = article.DatePublished
+		}
+		return meta.Title == "" || meta.Byline == "" || meta.PublishDate == ""
+	})
+}
@@ -0,0 +1,120 @@
+// Package reader extracts the main content of an article page using a
+// Readability-style scoring heuristic, then sanitizes the result to a
+// small allow-listed tag/attribute set so it can be rendered as a
+// distraction-free view that sidesteps most JS/CSP breakage on news sites.
+package reader
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+
+	"github.com/Halffd/nav/internal/rewriter"
+)
+
+// wordsPerMinute is the reading speed used to estimate ReadingMinutes.
+const wordsPerMinute = 265
+
+// Article is the extracted, sanitized main content of a page.
+type Article struct {
+	Title          string
+	Byline         string
+	PublishDate    string
+	Content        string // sanitized HTML
+	ReadingMinutes int
+}
+
+// candidateTags are the elements eligible to be scored as the main content
+// container.
+var candidateTags = []string{"article", "main", "div", "section"}
+
+// noiseSelectors are removed from the document before scoring: chrome that
+// is never the main content, regardless of how much text it contains.
+var noiseSelectors = []string{
+	"script", "style", "noscript", "nav", "aside", "footer",
+	"header", "form", "iframe", "[role='navigation']", "[role='banner']",
+	"[role='contentinfo']", ".advertisement", ".ad", "#comments",
+}
+
+// Extract scores doc's candidate containers by text length and link
+// density, picks the best one as the article body, and returns it
+// alongside metadata pulled from OpenGraph/JSON-LD tags. rw proxies inline
+// image sources in the extracted content through the existing rewriter so
+// they keep loading from the reader view.
+func Extract(doc *goquery.Document, rw *rewriter.Rewriter) (*Article, error) {
+	meta := extractMetadata(doc)
+
+	doc.Find(strings.Join(noiseSelectors, ", ")).Remove()
+
+	best := pickCandidate(doc)
+	if best == nil {
+		// Nothing scored: fall back to <body> as a whole rather than
+		// returning an empty article.
+		best = doc.Find("body")
+	}
+
+	best.Find("img[src]").Each(func(i int, s *goquery.Selection) {
+		if src, exists := s.Attr("src"); exists {
+			s.SetAttr("src", rw.ProxyURL(src))
+		}
+	})
+
+	sanitized := Sanitize(best)
+	words := len(strings.Fields(best.Text()))
+
+	return &Article{
+		Title:          meta.Title,
+		Byline:         meta.Byline,
+		PublishDate:    meta.PublishDate,
+		Content:        sanitized,
+		ReadingMinutes: max(1, words/wordsPerMinute),
+	}, nil
+}
+
+// pickCandidate returns the highest-scoring element among candidateTags,
+// or nil if the document has no text-bearing candidates at all.
+func pickCandidate(doc *goquery.Document) *goquery.Selection {
+	var best *goquery.Selection
+	bestScore := 0.0
+
+	for _, tag := range candidateTags {
+		doc.Find(tag).Each(func(i int, s *goquery.Selection) {
+			score := scoreCandidate(s)
+			if score > bestScore {
+				bestScore = score
+				best = s
+			}
+		})
+	}
+	return best
+}
+
+// scoreCandidate approximates Readability's content score: text length
+// penalized by link density (the fraction of the text that sits inside
+// <a> tags), so nav-like blocks of links don't win over prose.
+func scoreCandidate(s *goquery.Selection) float64 {
+	text := strings.TrimSpace(s.Text())
+	textLen := float64(len(text))
+	if textLen < 200 {
+		// Too short to plausibly be the article body.
+		return 0
+	}
+
+	linkLen := 0.0
+	s.Find("a").Each(func(i int, a *goquery.Selection) {
+		linkLen += float64(len(a.Text()))
+	})
+	linkDensity := linkLen / textLen
+
+	paragraphs := s.Find("p").Length()
+	score := textLen * (1 - linkDensity)
+	score += float64(paragraphs) * 25 // reward many paragraphs over one big blob
+	return score
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
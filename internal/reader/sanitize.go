@@ -0,0 +1,98 @@
+package reader
+
+import (
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+// allowedTags is the full set of tags the sanitized article is allowed to
+// contain. Anything else outside disallowedTags is unwrapped (its children
+// are kept, the wrapper itself is dropped) rather than deleted outright,
+// since wrapper divs/spans commonly hold real content.
+var allowedTags = map[string]bool{
+	"p": true, "a": true, "img": true, "figure": true, "figcaption": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"ul": true, "ol": true, "li": true, "blockquote": true,
+	"pre": true, "code": true, "em": true, "strong": true, "b": true, "i": true,
+	"br": true, "hr": true,
+	"table": true, "thead": true, "tbody": true, "tr": true, "td": true, "th": true,
+}
+
+// allowedAttrs lists, per tag, the attributes that survive sanitization.
+// Everything else (style, on*, id, class, data-*, ...) is stripped.
+var allowedAttrs = map[string][]string{
+	"a":   {"href", "title"},
+	"img": {"src", "alt", "title"},
+}
+
+// Sanitize rewrites sel in place — every tag not in allowedTags is
+// unwrapped (div/span/etc.) or removed (script/style/iframe/etc.), and
+// every attribute not explicitly allow-listed for that tag is stripped —
+// and returns the resulting HTML.
+func Sanitize(sel *goquery.Selection) string {
+	// Repeatedly unwrap disallowed container tags until none are left;
+	// goquery mutates in place so a single pass can miss newly-exposed
+	// nodes from nested wrappers.
+	for {
+		changed := false
+		sel.Find("*").Each(func(i int, s *goquery.Selection) {
+			tag := goquery.NodeName(s)
+			if allowedTags[tag] {
+				return
+			}
+			if _, disallowed := disallowedTags[tag]; disallowed {
+				s.Remove()
+				changed = true
+				return
+			}
+			// Unknown or wrapper tag: keep children, drop the tag itself.
+			s.ReplaceWithSelection(s.Contents())
+			changed = true
+		})
+		if !changed {
+			break
+		}
+	}
+
+	sel.Find("*").Each(func(i int, s *goquery.Selection) {
+		tag := goquery.NodeName(s)
+		keep := map[string]bool{}
+		for _, attr := range allowedAttrs[tag] {
+			keep[attr] = true
+		}
+		for _, attrNode := range nodeAttrs(s) {
+			if !keep[attrNode.Key] {
+				s.RemoveAttr(attrNode.Key)
+			}
+		}
+	})
+
+	html, _ := sel.Html()
+	return strings.TrimSpace(html)
+}
+
+// disallowedTags are always stripped entirely, content included, rather
+// than unwrapped — these are tags that can carry executable behavior or
+// are otherwise never safe to keep even without their attributes.
+var disallowedTags = map[string]struct{}{
+	"script": {}, "style": {}, "iframe": {}, "object": {}, "embed": {},
+	"form": {}, "input": {}, "button": {}, "select": {}, "textarea": {},
+	"svg": {}, "noscript": {},
+}
+
+type htmlAttr struct {
+	Key string
+	Val string
+}
+
+func nodeAttrs(s *goquery.Selection) []htmlAttr {
+	if len(s.Nodes) == 0 {
+		return nil
+	}
+	var attrs []htmlAttr
+	for _, a := range s.Nodes[0].Attr {
+		attrs = append(attrs, htmlAttr{Key: a.Key, Val: a.Val})
+	}
+	return attrs
+}
@@ -0,0 +1,41 @@
+package rewriter
+
+import "strings"
+
+// replaceURLLiterals scans s for substrings that look like absolute
+// http(s) URLs and passes each through fn, leaving everything else intact.
+// It is intentionally simple (no JS parsing) and only used for the
+// best-effort rewriting of inline event handlers.
+func replaceURLLiterals(s string, fn func(string) string) string {
+	var out strings.Builder
+	i := 0
+	for i < len(s) {
+		rest := s[i:]
+		start := strings.Index(rest, "http://")
+		startS := strings.Index(rest, "https://")
+		if startS != -1 && (start == -1 || startS < start) {
+			start = startS
+		}
+		if start == -1 {
+			out.WriteString(rest)
+			break
+		}
+		out.WriteString(rest[:start])
+		end := start
+		for end < len(rest) && !isURLTerminator(rest[end]) {
+			end++
+		}
+		out.WriteString(fn(rest[start:end]))
+		i += end
+	}
+	return out.String()
+}
+
+func isURLTerminator(b byte) bool {
+	switch b {
+	case '\'', '"', ' ', '\t', '\n', ')', '(', '<', '>':
+		return true
+	default:
+		return false
+	}
+}
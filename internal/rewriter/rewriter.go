@@ -0,0 +1,409 @@
+// Package rewriter rewrites every URL-bearing surface of a proxied HTML
+// document (attributes, inline CSS, inline event handlers, JSON-LD, meta
+// refresh) so that client-side navigation stays inside the proxy instead of
+// escaping to the origin site.
+package rewriter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/tdewolff/parse/v2"
+	"github.com/tdewolff/parse/v2/css"
+)
+
+// urlAttrs maps tag name -> attribute names that carry a URL on that tag.
+// Attributes valid on every tag (style and the on* event handlers) are not
+// listed here; RewriteDocument handles them through globalAttrs and
+// eventHandlerPrefix.
+var urlAttrs = map[string][]string{
+	"a":          {"href", "ping"},
+	"area":       {"href", "ping"},
+	"applet":     {"codebase", "archive"},
+	"audio":      {"src"},
+	"base":       {"href"},
+	"blockquote": {"cite"},
+	"body":       {"background"},
+	"button":     {"formaction"},
+	"del":        {"cite"},
+	"embed":      {"src"},
+	"form":       {"action"},
+	"frame":      {"src", "longdesc"},
+	"iframe":     {"src", "longdesc"},
+	"img":        {"src", "srcset", "longdesc"},
+	"input":      {"src", "formaction"},
+	"ins":        {"cite"},
+	"link":       {"href"},
+	"object":     {"data", "codebase", "archive"},
+	"q":          {"cite"},
+	"script":     {"src"},
+	"source":     {"src", "srcset"},
+	"table":      {"background"},
+	"td":         {"background"},
+	"th":         {"background"},
+	"track":      {"src"},
+	"video":      {"src", "poster"},
+}
+
+// remainingURLAttrs is urlAttrs minus the tags main.go's own "/" pipeline
+// already rewrites itself: a/form (the inline href/action loops),
+// iframe/img (processImages/processIframes), link (processHead's favicon
+// pass plus processCSS replacing stylesheet links outright), script
+// (processJS inlines the fetched body instead of rewriting src), and base
+// (left absolute, not proxied, intentionally). It's used by
+// RewriteMediaAttrs so the rest of the HTML5 URL attribute matrix --
+// audio/video/source/track/embed/object, table/td/th background,
+// button/input formaction, area, and the cite-bearing tags -- gets
+// rewritten too, without double-processing what main.go already handles.
+var remainingURLAttrs = map[string][]string{
+	"applet":     {"codebase", "archive"},
+	"area":       {"href", "ping"},
+	"audio":      {"src"},
+	"blockquote": {"cite"},
+	"body":       {"background"},
+	"button":     {"formaction"},
+	"del":        {"cite"},
+	"embed":      {"src"},
+	"frame":      {"src", "longdesc"},
+	"input":      {"src", "formaction"},
+	"ins":        {"cite"},
+	"object":     {"data", "codebase", "archive"},
+	"q":          {"cite"},
+	"source":     {"src", "srcset"},
+	"table":      {"background"},
+	"td":         {"background"},
+	"th":         {"background"},
+	"track":      {"src"},
+	"video":      {"src", "poster"},
+}
+
+// globalAttrs are rewritten on every element regardless of tag.
+var globalAttrs = []string{"style"}
+
+// eventHandlerPrefix catches inline event handlers such as onclick,
+// onerror, onload, etc. so that handlers built from string concatenation of
+// the origin URL don't leak it.
+const eventHandlerPrefix = "on"
+
+// Rewriter rewrites URLs found in an HTML document so they route back
+// through this proxy instead of pointing at the origin directly.
+type Rewriter struct {
+	baseURL   string
+	proxyPath string
+	signer    *Signer
+	nonce     string
+}
+
+// Option configures a Rewriter.
+type Option func(*Rewriter)
+
+// WithProxyPath overrides the path the rewriter points rewritten URLs at.
+// Defaults to "/?url=".
+func WithProxyPath(path string) Option {
+	return func(r *Rewriter) { r.proxyPath = path }
+}
+
+// WithSigner attaches an HMAC Signer so every rewritten URL is tamper-evident.
+func WithSigner(s *Signer) Option {
+	return func(r *Rewriter) { r.signer = s }
+}
+
+// WithNonce stamps every <style>/<script> tag StampNonces touches with the
+// given per-request CSP nonce.
+func WithNonce(nonce string) Option {
+	return func(r *Rewriter) { r.nonce = nonce }
+}
+
+// New returns a Rewriter that resolves relative URLs against baseURL.
+func New(baseURL string, opts ...Option) *Rewriter {
+	r := &Rewriter{
+		baseURL:   baseURL,
+		proxyPath: "/?url=",
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// ProxyURL resolves target against the rewriter's base URL and returns the
+// proxied form a browser should be given instead of the original URL.
+func (r *Rewriter) ProxyURL(target string) string {
+	if target == "" || strings.HasPrefix(target, "#") || strings.HasPrefix(target, "javascript:") ||
+		strings.HasPrefix(target, "data:") || strings.HasPrefix(target, "mailto:") {
+		return target
+	}
+	absolute := r.makeAbsoluteURL(target)
+	if r.signer != nil {
+		return fmt.Sprintf("%s%s&sig=%s", r.proxyPath, absolute, r.signer.Sign(absolute))
+	}
+	return fmt.Sprintf("%s%s", r.proxyPath, absolute)
+}
+
+func (r *Rewriter) makeAbsoluteURL(resourceURL string) string {
+	if strings.HasPrefix(resourceURL, "http://") || strings.HasPrefix(resourceURL, "https://") || strings.HasPrefix(resourceURL, "//") {
+		return resourceURL
+	}
+	return fmt.Sprintf("%s/%s", strings.TrimSuffix(r.baseURL, "/"), strings.TrimPrefix(resourceURL, "/"))
+}
+
+// RewriteSrcset rewrites every URL candidate inside a srcset attribute,
+// preserving the descriptor (e.g. "1x", "480w") after each one.
+func (r *Rewriter) RewriteSrcset(value string) string {
+	candidates := strings.Split(value, ",")
+	out := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "" {
+			continue
+		}
+		parts := strings.Fields(candidate)
+		if len(parts) == 0 {
+			continue
+		}
+		parts[0] = r.ProxyURL(parts[0])
+		out = append(out, strings.Join(parts, " "))
+	}
+	return strings.Join(out, ", ")
+}
+
+// rewriteAttrs rewrites every attr in tags (tag name -> attribute names)
+// found in doc, proxying each value (or running it through RewriteSrcset
+// for "srcset" attributes).
+func (r *Rewriter) rewriteAttrs(doc *goquery.Document, tags map[string][]string) {
+	for tag, attrs := range tags {
+		sel := doc.Find(tag)
+		for _, attr := range attrs {
+			sel.Each(func(i int, s *goquery.Selection) {
+				value, exists := s.Attr(attr)
+				if !exists {
+					return
+				}
+				if attr == "srcset" {
+					s.SetAttr(attr, r.RewriteSrcset(value))
+					return
+				}
+				s.SetAttr(attr, r.ProxyURL(value))
+			})
+		}
+	}
+}
+
+// RewriteMediaAttrs rewrites the remainingURLAttrs matrix: the URL-bearing
+// attributes main.go's own "/" pipeline doesn't already cover through its
+// own per-purpose passes (img/iframe/form/a/link/script/base). Call this
+// from a pipeline that runs those passes itself instead of RewriteDocument,
+// so surfaces like <video src>, <audio src>, and <button formaction> still
+// get proxied without double-rewriting what's already been handled.
+func (r *Rewriter) RewriteMediaAttrs(doc *goquery.Document) {
+	r.rewriteAttrs(doc, remainingURLAttrs)
+}
+
+// RewriteDocument walks doc and rewrites every URL-bearing attribute,
+// inline style block, inline event handler, meta refresh, and JSON-LD
+// script it finds.
+func (r *Rewriter) RewriteDocument(doc *goquery.Document) {
+	r.rewriteAttrs(doc, urlAttrs)
+
+	doc.Find("*").Each(func(i int, s *goquery.Selection) {
+		for _, attrNode := range s.Nodes[0].Attr {
+			if strings.HasPrefix(attrNode.Key, eventHandlerPrefix) {
+				// Inline handlers are left in place for execution, but any
+				// absolute reference to the origin inside them is replaced
+				// so navigation triggered from script stays proxied.
+				s.SetAttr(attrNode.Key, r.rewriteInlineScript(attrNode.Val))
+			}
+		}
+	})
+
+	for _, attr := range globalAttrs {
+		doc.Find("[" + attr + "]").Each(func(i int, s *goquery.Selection) {
+			value, exists := s.Attr(attr)
+			if !exists {
+				return
+			}
+			s.SetAttr(attr, r.RewriteCSS(value))
+		})
+	}
+
+	doc.Find("style").Each(func(i int, s *goquery.Selection) {
+		s.SetText(r.RewriteCSS(s.Text()))
+	})
+
+	r.RewriteMetaRefresh(doc)
+	r.RewriteJSONLD(doc)
+	r.StampNonces(doc)
+}
+
+// StampNonces sets the nonce attribute on every <script> and <style>
+// element in doc, inline or external, so they satisfy the per-request CSP
+// this proxy emits. It's a no-op if the Rewriter wasn't built with
+// WithNonce. Call it last, after any step that injects or replaces
+// script/style elements, so nothing added earlier is missed.
+func (r *Rewriter) StampNonces(doc *goquery.Document) {
+	if r.nonce == "" {
+		return
+	}
+	doc.Find("script, style").Each(func(i int, s *goquery.Selection) {
+		s.SetAttr("nonce", r.nonce)
+	})
+}
+
+// Nonce returns the CSP nonce this Rewriter was built with, or "" if none.
+func (r *Rewriter) Nonce() string {
+	return r.nonce
+}
+
+// StyleTag wraps css in a <style> element carrying the current nonce, for
+// callers that build the tag as an HTML string rather than through
+// goquery (e.g. replacing a <link rel="stylesheet"> with inline CSS).
+func (r *Rewriter) StyleTag(css string) string {
+	if r.nonce == "" {
+		return fmt.Sprintf("<style>%s</style>", css)
+	}
+	return fmt.Sprintf("<style nonce=%q>%s</style>", r.nonce, css)
+}
+
+// rewriteInlineScript performs a best-effort substitution of any absolute
+// URL found inside an inline event handler body. It does not attempt to
+// parse JavaScript; it rewrites every substring starting with http:// or
+// https:// up to the next quote, whitespace, parenthesis or angle bracket,
+// which covers the common `onclick="location.href='https://...'"` pattern.
+func (r *Rewriter) rewriteInlineScript(script string) string {
+	if !strings.Contains(script, "http://") && !strings.Contains(script, "https://") {
+		return script
+	}
+	return replaceURLLiterals(script, r.ProxyURL)
+}
+
+func (r *Rewriter) RewriteMetaRefresh(doc *goquery.Document) {
+	doc.Find("meta[http-equiv]").Each(func(i int, s *goquery.Selection) {
+		if !strings.EqualFold(s.AttrOr("http-equiv", ""), "refresh") {
+			return
+		}
+		content, exists := s.Attr("content")
+		if !exists {
+			return
+		}
+		parts := strings.SplitN(content, ";", 2)
+		if len(parts) != 2 {
+			return
+		}
+		urlPart := strings.TrimSpace(parts[1])
+		urlPart = strings.TrimPrefix(urlPart, "url=")
+		urlPart = strings.TrimPrefix(urlPart, "URL=")
+		urlPart = strings.Trim(urlPart, "'\"")
+		s.SetAttr("content", fmt.Sprintf("%s; url=%s", parts[0], r.ProxyURL(urlPart)))
+	})
+}
+
+// jsonLDURLKeys are the fields schema.org JSON-LD commonly uses to carry a
+// URL that should be proxied.
+var jsonLDURLKeys = []string{"@id", "url", "contentUrl", "thumbnailUrl"}
+
+func (r *Rewriter) RewriteJSONLD(doc *goquery.Document) {
+	doc.Find(`script[type="application/ld+json"]`).Each(func(i int, s *goquery.Selection) {
+		var payload interface{}
+		if err := json.Unmarshal([]byte(s.Text()), &payload); err != nil {
+			return
+		}
+		r.rewriteJSONLDValue(payload)
+		rewritten, err := json.Marshal(payload)
+		if err != nil {
+			return
+		}
+		s.SetText(string(rewritten))
+	})
+}
+
+func (r *Rewriter) rewriteJSONLDValue(v interface{}) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for _, key := range jsonLDURLKeys {
+			if s, ok := val[key].(string); ok && looksLikeURL(s) {
+				val[key] = r.ProxyURL(s)
+			}
+		}
+		for _, nested := range val {
+			r.rewriteJSONLDValue(nested)
+		}
+	case []interface{}:
+		for _, nested := range val {
+			r.rewriteJSONLDValue(nested)
+		}
+	}
+}
+
+func looksLikeURL(s string) bool {
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://") || strings.HasPrefix(s, "/")
+}
+
+// RewriteCSS rewrites every url(...) function and @import target in a CSS
+// source string using the real CSS tokenizer (rather than a regex) so
+// quoting, whitespace, and comments inside the value are handled correctly.
+// A bare `@import "foo.css";` (no url()) tokenizes as an AtKeywordToken
+// followed by a StringToken, so that string is rewritten too when it
+// directly follows an @import keyword (whitespace/comments in between
+// don't break the association; anything else does).
+func (r *Rewriter) RewriteCSS(src string) string {
+	lexer := css.NewLexer(parse.NewInputString(src))
+	var out strings.Builder
+	afterImport := false
+	for {
+		tt, data := lexer.Next()
+		if tt == css.ErrorToken {
+			break
+		}
+		switch tt {
+		case css.URLToken:
+			out.WriteString(r.rewriteCSSURLToken(string(data)))
+			afterImport = false
+		case css.StringToken:
+			if afterImport {
+				out.WriteString(r.rewriteCSSStringToken(string(data)))
+			} else {
+				out.Write(data)
+			}
+			afterImport = false
+		case css.AtKeywordToken:
+			out.Write(data)
+			afterImport = strings.EqualFold(string(data), "@import")
+		case css.WhitespaceToken, css.CommentToken:
+			out.Write(data)
+		default:
+			out.Write(data)
+			afterImport = false
+		}
+	}
+	return out.String()
+}
+
+// rewriteCSSURLToken rewrites a single url(...) token, preserving its
+// quoting style.
+func (r *Rewriter) rewriteCSSURLToken(token string) string {
+	inner := strings.TrimSuffix(strings.TrimPrefix(token, "url("), ")")
+	inner = strings.TrimSpace(inner)
+	quote := ""
+	if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') {
+		quote = string(inner[0])
+		inner = inner[1 : len(inner)-1]
+	}
+	rewritten := r.ProxyURL(inner)
+	return fmt.Sprintf("url(%s%s%s)", quote, rewritten, quote)
+}
+
+// rewriteCSSStringToken rewrites a quoted string token's contents as a URL,
+// preserving its quoting style. Used for the @import "foo.css" form, where
+// the target is a bare string rather than a url(...) function.
+func (r *Rewriter) rewriteCSSStringToken(token string) string {
+	inner := token
+	quote := ""
+	if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') {
+		quote = string(inner[0])
+		inner = inner[1 : len(inner)-1]
+	}
+	rewritten := r.ProxyURL(inner)
+	return fmt.Sprintf("%s%s%s", quote, rewritten, quote)
+}
@@ -0,0 +1,95 @@
+package rewriter
+
+import "fmt"
+
+// shimTemplate is injected as the first <script> in <head> so runtime
+// navigation (fetch/XHR, Worker, WebSocket, history API, location setters)
+// also gets routed back through the proxy instead of escaping to the
+// origin directly. %q is the proxy path (e.g. "/?url="), quoted as a JS string.
+const shimTemplate = `(function(proxyPath){
+	function proxied(url) {
+		try {
+			var abs = new URL(url, location.href).href;
+			if (abs.indexOf(location.origin) === 0) return url;
+			return proxyPath + encodeURIComponent(abs);
+		} catch (e) {
+			return url;
+		}
+	}
+
+	var origFetch = window.fetch;
+	if (origFetch) {
+		window.fetch = function(input, init) {
+			if (typeof input === "string") input = proxied(input);
+			else if (input && input.url) input = new Request(proxied(input.url), input);
+			return origFetch.call(window, input, init);
+		};
+	}
+
+	var origOpen = XMLHttpRequest.prototype.open;
+	XMLHttpRequest.prototype.open = function(method, url) {
+		arguments[1] = proxied(url);
+		return origOpen.apply(this, arguments);
+	};
+
+	if (window.Worker) {
+		var OrigWorker = window.Worker;
+		window.Worker = function(url, opts) {
+			return new OrigWorker(proxied(url), opts);
+		};
+		window.Worker.prototype = OrigWorker.prototype;
+	}
+
+	if (window.WebSocket) {
+		var OrigWebSocket = window.WebSocket;
+		window.WebSocket = function(url, protocols) {
+			return new OrigWebSocket(proxied(url), protocols);
+		};
+		window.WebSocket.prototype = OrigWebSocket.prototype;
+	}
+
+	var origPushState = history.pushState;
+	var origReplaceState = history.replaceState;
+	history.pushState = function(state, title, url) {
+		return origPushState.call(this, state, title, url ? proxied(url) : url);
+	};
+	history.replaceState = function(state, title, url) {
+		return origReplaceState.call(this, state, title, url ? proxied(url) : url);
+	};
+
+	try {
+		var locProto = Object.getPrototypeOf(window.location);
+		var hrefDescriptor = Object.getOwnPropertyDescriptor(locProto, "href");
+		if (hrefDescriptor && hrefDescriptor.set && hrefDescriptor.configurable) {
+			Object.defineProperty(locProto, "href", {
+				get: hrefDescriptor.get,
+				set: function(url) { return hrefDescriptor.set.call(this, proxied(url)); },
+				configurable: true,
+				enumerable: hrefDescriptor.enumerable
+			});
+		}
+
+		var origAssign = locProto.assign;
+		locProto.assign = function(url) { return origAssign.call(this, proxied(url)); };
+
+		var origReplace = locProto.replace;
+		locProto.replace = function(url) { return origReplace.call(this, proxied(url)); };
+	} catch (e) {
+		// Some browsers make Location.prototype.href/assign/replace
+		// non-configurable; runtime navigation via direct assignment falls
+		// back to the server-side rewrite of <a>/<form> for those cases.
+	}
+})(%q);`
+
+// Shim returns the client-side navigation shim as a <script> tag, with
+// proxyPath baked in as the prefix every runtime-constructed URL is routed
+// through. If nonce is non-empty it's stamped onto the tag so the script
+// runs under a nonce-gated CSP; StampNonces also covers this tag on any
+// later pass, so an empty nonce here is fine too.
+func Shim(proxyPath, nonce string) string {
+	body := fmt.Sprintf(shimTemplate, proxyPath)
+	if nonce == "" {
+		return fmt.Sprintf("<script>%s</script>", body)
+	}
+	return fmt.Sprintf("<script nonce=%q>%s</script>", nonce, body)
+}
@@ -0,0 +1,44 @@
+package rewriter
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+)
+
+// Signer produces and verifies HMAC signatures over proxied URLs so that
+// `/?url=` can refuse to fetch unsigned or third-party-crafted URLs once a
+// secret is configured. This mirrors the "proxy_url" signing pattern other
+// feed readers use for proxied media links.
+type Signer struct {
+	secret []byte
+}
+
+// NewSigner returns a Signer keyed by secret. A Signer with an empty secret
+// is never used directly; callers should check for an empty PROXY_SECRET
+// and skip signing entirely in that case.
+func NewSigner(secret string) *Signer {
+	return &Signer{secret: []byte(secret)}
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 of url under the signer's secret.
+func (s *Signer) Sign(url string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(url))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify reports whether sig is the correct signature for url, using a
+// constant-time comparison to avoid leaking timing information.
+func (s *Signer) Verify(url, sig string) bool {
+	expected, err := hex.DecodeString(s.Sign(url))
+	if err != nil {
+		return false
+	}
+	got, err := hex.DecodeString(sig)
+	if err != nil {
+		return false
+	}
+	return subtle.ConstantTimeCompare(expected, got) == 1
+}
@@ -0,0 +1,209 @@
+// Package youtube extracts playable video metadata from YouTube URLs via
+// yt-dlp, so proxied pages get a native <video> element instead of an
+// iframe embed pointed at google.com. When yt-dlp isn't available on the
+// host, callers should fall back to IframeFallback.
+package youtube
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultEmbedURLBase is the embed base IframeFallback uses when it's given
+// an empty one: the privacy-respecting youtube-nocookie.com domain. main.go
+// replaces it at startup with --youtube-embed-url / YOUTUBE_EMBED_URL so
+// operators can point it at a self-hosted Invidious/Piped instance instead.
+const DefaultEmbedURLBase = "https://www.youtube-nocookie.com/embed/"
+
+var urlPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?:youtube\.com|youtube-nocookie\.com)/watch\?.*\bv=([\w-]+)`),
+	regexp.MustCompile(`youtube\.com/shorts/([\w-]+)`),
+	regexp.MustCompile(`youtu\.be/([\w-]+)`),
+	regexp.MustCompile(`music\.youtube\.com/watch\?.*\bv=([\w-]+)`),
+}
+
+// playlistPattern matches a bare playlist link, which carries a list ID
+// rather than a video ID.
+var playlistPattern = regexp.MustCompile(`youtube\.com/playlist\?.*\blist=([\w-]+)`)
+
+// Match reports whether rawURL points at a YouTube video or playlist of any
+// of the supported URL shapes (watch, Shorts, youtu.be, music.youtube.com,
+// playlists).
+func Match(rawURL string) bool {
+	return VideoID(rawURL) != "" || playlistPattern.MatchString(rawURL)
+}
+
+// VideoID extracts the video ID from any of the supported URL shapes, or
+// "" if rawURL doesn't look like a single-video URL (e.g. it's a bare
+// playlist link).
+func VideoID(rawURL string) string {
+	for _, pattern := range urlPatterns {
+		if m := pattern.FindStringSubmatch(rawURL); len(m) == 2 {
+			return m[1]
+		}
+	}
+	return ""
+}
+
+// Chapter is a named timestamp range within a video, as reported by
+// yt-dlp's "chapters" field.
+type Chapter struct {
+	Title     string  `json:"title"`
+	StartTime float64 `json:"start_time"`
+	EndTime   float64 `json:"end_time"`
+}
+
+// Caption is a subtitle/caption track in one language.
+type Caption struct {
+	Language string `json:"language"`
+	URL      string `json:"url"`
+	Ext      string `json:"ext"`
+}
+
+// Source is one direct, playable media URL yt-dlp resolved for a format.
+type Source struct {
+	URL      string `json:"url"`
+	MimeType string `json:"mime_type"`
+	Height   int    `json:"height"`
+}
+
+// Video holds the metadata and direct media URLs yt-dlp extracted for a
+// single video.
+type Video struct {
+	ID         string
+	Title      string             `json:"title"`
+	Uploader   string             `json:"uploader"`
+	Duration   time.Duration      `json:"-"`
+	DurationS  float64            `json:"duration"`
+	Thumbnails []ytThumbnail      `json:"thumbnails"`
+	Chapters   []Chapter          `json:"chapters"`
+	Captions   map[string][]ytSub `json:"subtitles"`
+	Formats    []ytFormat         `json:"formats"`
+}
+
+type ytThumbnail struct {
+	URL string `json:"url"`
+}
+
+type ytSub struct {
+	URL string `json:"url"`
+	Ext string `json:"ext"`
+}
+
+type ytFormat struct {
+	URL      string  `json:"url"`
+	Ext      string  `json:"ext"`
+	Height   int     `json:"height"`
+	Vcodec   string  `json:"vcodec"`
+	Acodec   string  `json:"acodec"`
+	Protocol string  `json:"protocol"`
+	TBR      float64 `json:"tbr"`
+}
+
+// Available reports whether yt-dlp is installed and on $PATH.
+func Available() bool {
+	_, err := exec.LookPath("yt-dlp")
+	return err == nil
+}
+
+// Extract shells out to `yt-dlp -j <url>` and parses its metadata dump into
+// a Video. The caller should check Available() first and fall back to
+// IframeFallback if yt-dlp isn't installed.
+func Extract(ctx context.Context, videoURL string) (*Video, error) {
+	cmd := exec.CommandContext(ctx, "yt-dlp", "-j", "--no-playlist", videoURL)
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("yt-dlp extraction failed: %w", err)
+	}
+
+	var v Video
+	if err := json.Unmarshal(out, &v); err != nil {
+		return nil, fmt.Errorf("parsing yt-dlp output: %w", err)
+	}
+	v.ID = VideoID(videoURL)
+	v.Duration = time.Duration(v.DurationS * float64(time.Second))
+	return &v, nil
+}
+
+// Sources returns the playable <source> candidates for this video, sorted
+// by descending quality, limited to formats carrying both an audio and
+// video codec (yt-dlp often also returns video-only/audio-only formats,
+// which would need MSE muxing to play directly and so are skipped here).
+func (v *Video) Sources() []Source {
+	var sources []Source
+	for _, f := range v.Formats {
+		if f.Vcodec == "" || f.Vcodec == "none" || f.Acodec == "" || f.Acodec == "none" {
+			continue
+		}
+		sources = append(sources, Source{
+			URL:      f.URL,
+			MimeType: mimeTypeForExt(f.Ext),
+			Height:   f.Height,
+		})
+	}
+	sort.SliceStable(sources, func(i, j int) bool {
+		return sources[i].Height > sources[j].Height
+	})
+	return sources
+}
+
+func mimeTypeForExt(ext string) string {
+	switch ext {
+	case "mp4":
+		return "video/mp4"
+	case "webm":
+		return "video/webm"
+	default:
+		return "video/" + ext
+	}
+}
+
+// RenderVideo renders a native <video> element sourced from the formats
+// yt-dlp resolved, in place of the iframe embed. proxyURL rewrites each
+// source/poster URL before it's embedded: yt-dlp resolves direct
+// googlevideo.com URLs, which are cross-origin to the proxy, so the CSP's
+// same-origin media-src would otherwise block them from playing.
+func RenderVideo(v *Video, proxyURL func(string) string) string {
+	var b strings.Builder
+	b.WriteString(`<div class="video-container"><video controls poster="`)
+	if len(v.Thumbnails) > 0 {
+		b.WriteString(proxyURL(v.Thumbnails[len(v.Thumbnails)-1].URL))
+	}
+	b.WriteString(`">`)
+	for _, s := range v.Sources() {
+		fmt.Fprintf(&b, `<source src=%q type=%q>`, proxyURL(s.URL), s.MimeType)
+	}
+	b.WriteString(`Your browser does not support the video tag.</video>`)
+	// v.Title and v.Uploader come straight from yt-dlp's JSON dump, i.e.
+	// attacker-influenceable video metadata, so they need escaping here the
+	// same way reader/metadata.go relies on html/template for its own
+	// extracted metadata.
+	fmt.Fprintf(&b, `<h2 class="yt-title">%s</h2><p class="yt-uploader">%s</p>`, html.EscapeString(v.Title), html.EscapeString(v.Uploader))
+	b.WriteString(`</div>`)
+	return b.String()
+}
+
+// IframeFallback renders the original iframe embed against embedURLBase,
+// used when yt-dlp is unavailable or extraction failed. embedURLBase
+// defaults to DefaultEmbedURLBase but can be pointed at a self-hosted
+// Invidious/Piped instance.
+func IframeFallback(videoID, embedURLBase string) string {
+	if embedURLBase == "" {
+		embedURLBase = DefaultEmbedURLBase
+	}
+	return `<div class="video-container">
+		<iframe
+			src="` + strings.TrimSuffix(embedURLBase, "/") + `/` + videoID + `"
+			frameborder="0"
+			allowfullscreen="true"
+			allow="accelerometer; autoplay; clipboard-write; encrypted-media; gyroscope; picture-in-picture">
+		</iframe>
+	</div>`
+}
@@ -1,23 +1,43 @@
 package main
 
 import (
+	"bytes"
+	"context"
 	"flag"
 	"fmt"
+	"html/template"
 	"io"
 	"log"
 	"net/http"
+	neturl "net/url"
+	"path/filepath"
+	"runtime"
 	"strings"
-	"html/template"
-	"time"
 	"sync"
-	"runtime"
+	"time"
 
+	"os"
+
+	"github.com/PuerkitoBio/goquery"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/template/html/v2"
-	"github.com/PuerkitoBio/goquery"
 	"github.com/tdewolff/minify/v2"
 	"github.com/tdewolff/minify/v2/css"
 	"github.com/tdewolff/minify/v2/js"
+	"golang.org/x/sync/errgroup"
+
+	"github.com/Halffd/nav/internal/cache"
+	"github.com/Halffd/nav/internal/content"
+	_ "github.com/Halffd/nav/internal/content/audio"
+	_ "github.com/Halffd/nav/internal/content/code"
+	_ "github.com/Halffd/nav/internal/content/images"
+	_ "github.com/Halffd/nav/internal/content/pdf"
+	_ "github.com/Halffd/nav/internal/content/text"
+	_ "github.com/Halffd/nav/internal/content/video"
+	"github.com/Halffd/nav/internal/csp"
+	"github.com/Halffd/nav/internal/reader"
+	"github.com/Halffd/nav/internal/rewriter"
+	"github.com/Halffd/nav/internal/sitespecific/youtube"
 )
 
 // Add debug logger and statistics
@@ -49,11 +69,11 @@ func (ds *DebugStats) logRequest(entry RequestLogEntry) {
 	}
 	ds.Lock()
 	defer ds.Unlock()
-	
+
 	ds.RequestCount++
 	ds.BytesProcessed += entry.Size
 	ds.RequestLog = append(ds.RequestLog, entry)
-	
+
 	log.Printf("[DEBUG] Request: %s, Duration: %v, Status: %d, Size: %d bytes\n",
 		entry.URL, entry.Duration, entry.Status, entry.Size)
 }
@@ -75,26 +95,165 @@ func (ds *DebugStats) printStats() {
 	log.Printf("Total Bytes Processed: %.2f MB", float64(ds.BytesProcessed)/1024/1024)
 	log.Printf("Memory Usage: %.2f MB", float64(m.Alloc)/1024/1024)
 	log.Printf("Goroutines: %d", runtime.NumGoroutine())
+	if resourceCache != nil {
+		cacheStats := resourceCache.Stats()
+		log.Printf("Cache Hits: %d, Misses: %d", cacheStats.Hits, cacheStats.Misses)
+	}
 	log.Printf("====================\n")
 }
 
 type ResourceProcessor struct {
-	baseURL string
-	minifier *minify.M
+	baseURL   string
+	minifier  *minify.M
+	rewriter  *rewriter.Rewriter
 	debugInfo struct {
 		ResourcesProcessed int
-		BytesProcessed    int64
+		BytesProcessed     int64
 	}
 }
 
-func NewResourceProcessor(baseURL string) *ResourceProcessor {
+// proxySigner signs and verifies proxied URLs with an HMAC when
+// --proxy-secret/PROXY_SECRET is configured. Nil means signing is disabled.
+var proxySigner *rewriter.Signer
+
+// signedRedirect mints a signature for url and returns the canonical,
+// signed form of path ("/" or "/reader") pointing at it. Only called once
+// sameOriginReferer has confirmed the request came from this proxy's own
+// index-page form, not a third-party link that simply omitted ?sig=.
+func signedRedirect(path, url string) string {
+	return fmt.Sprintf("%s?url=%s&sig=%s", path, neturl.QueryEscape(url), neturl.QueryEscape(proxySigner.Sign(url)))
+}
+
+// sameOriginReferer reports whether c carries a Referer pointing back at
+// this proxy's own host. An unsigned ?url= is only allowed to be signed on
+// the fly when it demonstrably came from this proxy's own form submission;
+// a third-party-crafted link has no way to forge a Referer naming this
+// proxy's host, so this is what actually distinguishes "the user just typed
+// a URL into our own box" from "an attacker handed the victim a bare,
+// unsigned /?url=... link".
+func sameOriginReferer(c *fiber.Ctx) bool {
+	referer := c.Get("Referer")
+	if referer == "" {
+		return false
+	}
+	parsed, err := neturl.Parse(referer)
+	if err != nil || parsed.Host == "" {
+		return false
+	}
+	return parsed.Host == string(c.Request().Host())
+}
+
+// resourceCache backs every origin fetch (page HTML, CSS, JS) with
+// ETag/Last-Modified revalidation and singleflight coalescing. It's
+// initialized in main() once the cache CLI flags are parsed.
+var resourceCache *cache.Cache
+
+// workerPoolSize bounds how many external CSS/JS fetches processCSS and
+// processJS run concurrently (--worker-pool-size). fetchTimeout and
+// maxRetry configure each individual fetch those pools make
+// (--fetch-timeout, --max-retry), distinct from the overall page timeout
+// resourceCache's HTTP client enforces.
+var (
+	workerPoolSize = 8
+	fetchTimeout   = 5 * time.Second
+	maxRetry       = 2
+)
+
+// decorateFetchRequest sets the headers the proxy has always sent to
+// origins (a real-looking User-Agent, Accept, Sec-Fetch-*) on every
+// request resourceCache makes, so caching doesn't change what origins see.
+func decorateFetchRequest(req *http.Request) {
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.5")
+	req.Header.Set("Sec-Fetch-Dest", "document")
+	req.Header.Set("Sec-Fetch-Mode", "navigate")
+	req.Header.Set("Sec-Fetch-Site", "none")
+	req.Header.Set("Sec-Fetch-User", "?1")
+	req.Header.Set("Upgrade-Insecure-Requests", "1")
+}
+
+// entryToResponse wraps a cached entry in a synthetic *http.Response so it
+// can be handed to a content.Handler. Only the Content-Type header and the
+// body are carried over; StatusCode is always 200.
+func entryToResponse(entry *cache.Entry) *http.Response {
+	header := make(http.Header)
+	header.Set("Content-Type", entry.ContentType)
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     header,
+		Body:       io.NopCloser(bytes.NewReader(entry.Body)),
+	}
+}
+
+// rangeClient issues origin requests for byte-range (Range header) requests
+// directly, bypassing resourceCache entirely: the cache always issues a
+// plain GET and buffers the full body in memory (internal/cache/fetcher.go),
+// which can't serve partial content and would pull a multi-GB video fully
+// into RAM on first request. It has no Timeout because a single range can
+// legitimately take longer to stream than a page fetch should.
+var rangeClient = &http.Client{
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		if len(via) >= 10 {
+			return fmt.Errorf("too many redirects")
+		}
+		return nil
+	},
+}
+
+// proxyRange forwards a client's Range request straight to the origin and
+// streams the response back unchanged (status, Content-Range/Accept-Ranges/
+// Content-Length headers, and body), so native browser seeking against
+// audio/video served through this proxy actually works.
+func proxyRange(c *fiber.Ctx, url, rangeHeader string) error {
+	req, err := http.NewRequestWithContext(c.Context(), http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	decorateFetchRequest(req)
+	req.Header.Set("Range", rangeHeader)
+
+	resp, err := rangeClient.Do(req)
+	if err != nil {
+		return c.Status(fiber.StatusBadGateway).SendString(fmt.Sprintf("Error fetching range: %v", err))
+	}
+	defer resp.Body.Close()
+
+	c.Status(resp.StatusCode)
+	for _, header := range []string{"Content-Type", "Content-Length", "Content-Range", "Accept-Ranges", "Cache-Control"} {
+		if v := resp.Header.Get(header); v != "" {
+			c.Set(header, v)
+		}
+	}
+	return c.SendStream(resp.Body)
+}
+
+// youtubeEmbedURL is the base embed URL used for the iframe fallback when
+// yt-dlp isn't available, configured via --youtube-embed-url/YOUTUBE_EMBED_URL
+// so operators can point it at a self-hosted Invidious/Piped instance.
+var youtubeEmbedURL = youtube.DefaultEmbedURLBase
+
+func youtubeIframeFallback(videoID string) string {
+	return youtube.IframeFallback(videoID, youtubeEmbedURL)
+}
+
+func NewResourceProcessor(baseURL, nonce string) *ResourceProcessor {
 	m := minify.New()
 	m.AddFunc("text/css", css.Minify)
 	m.AddFunc("application/javascript", js.Minify)
 
+	var opts []rewriter.Option
+	if proxySigner != nil {
+		opts = append(opts, rewriter.WithSigner(proxySigner))
+	}
+	if nonce != "" {
+		opts = append(opts, rewriter.WithNonce(nonce))
+	}
+
 	return &ResourceProcessor{
-		baseURL: baseURL,
+		baseURL:  baseURL,
 		minifier: m,
+		rewriter: rewriter.New(baseURL, opts...),
 	}
 }
 
@@ -104,60 +263,128 @@ func (rp *ResourceProcessor) logDebug(format string, args ...interface{}) {
 	}
 }
 
+// cssTask is one external stylesheet discovered in the document, paired
+// with the <link> selection it will replace once fetched.
+type cssTask struct {
+	sel *goquery.Selection
+	url string
+}
+
+// processCSS fetches every external stylesheet through a bounded worker
+// pool (sized by --worker-pool-size) instead of one at a time, then
+// applies the results back to the DOM once all fetches complete.
 func (rp *ResourceProcessor) processCSS(doc *goquery.Document) error {
 	rp.logDebug("Processing CSS resources")
-	// Process <link> tags for external CSS
+
+	var tasks []cssTask
 	doc.Find("link[rel='stylesheet']").Each(func(i int, s *goquery.Selection) {
 		if href, exists := s.Attr("href"); exists {
-			absoluteURL := rp.makeAbsoluteURL(href)
-			css, err := rp.fetchAndMinifyCSS(absoluteURL)
+			tasks = append(tasks, cssTask{sel: s, url: rp.makeAbsoluteURL(href)})
+		}
+	})
+
+	results := make([]string, len(tasks))
+	ok := make([]bool, len(tasks))
+
+	g := new(errgroup.Group)
+	sem := make(chan struct{}, workerPoolSize)
+	for i, task := range tasks {
+		i, task := i, task
+		g.Go(func() error {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			css, err := rp.fetchAndMinifyCSS(task.url)
 			if err == nil {
-				// Replace external stylesheet with inline CSS
-				s.ReplaceWithHtml(fmt.Sprintf("<style>%s</style>", css))
+				results[i] = css
+				ok[i] = true
 			}
+			return nil
+		})
+	}
+	g.Wait()
+
+	for i, task := range tasks {
+		if ok[i] {
+			// Replace external stylesheet with inline CSS, rewriting any
+			// url(...)/@import targets it contains so they stay proxied.
+			task.sel.ReplaceWithHtml(rp.rewriter.StyleTag(rp.rewriter.RewriteCSS(results[i])))
 		}
-	})
+	}
 
 	// Process inline CSS
 	doc.Find("style").Each(func(i int, s *goquery.Selection) {
 		css := s.Text()
 		minified, err := rp.minifier.String("text/css", css)
-		if err == nil {
-			s.SetText(minified)
+		if err != nil {
+			minified = css
 		}
+		s.SetText(rp.rewriter.RewriteCSS(minified))
 	})
 
 	rp.debugInfo.ResourcesProcessed++
 	return nil
 }
 
+// jsTask is one external script discovered in the document, paired with
+// the <script> selection it will be applied to once fetched.
+type jsTask struct {
+	sel *goquery.Selection
+	url string
+}
+
+// processJS fetches every external script through the same bounded worker
+// pool processCSS uses, then applies the results back to the DOM once all
+// fetches complete.
 func (rp *ResourceProcessor) processJS(doc *goquery.Document) error {
 	rp.logDebug("Processing JavaScript resources")
-	// Process <script> tags for external JavaScript
+
+	var tasks []jsTask
 	doc.Find("script[src]").Each(func(i int, s *goquery.Selection) {
-		if src, exists := s.Attr("src"); exists {
-			// Keep YouTube player scripts and other essential external scripts
-			if strings.Contains(src, "youtube.com") {
-				s.SetAttr("src", rp.makeAbsoluteURL(src))
-				return
-			}
+		src, exists := s.Attr("src")
+		if !exists {
+			return
+		}
+		// Keep YouTube player scripts and other essential external scripts
+		if strings.Contains(src, "youtube.com") {
+			s.SetAttr("src", rp.makeAbsoluteURL(src))
+			return
+		}
+		tasks = append(tasks, jsTask{sel: s, url: rp.makeAbsoluteURL(src)})
+	})
 
-			absoluteURL := rp.makeAbsoluteURL(src)
-			js, err := rp.fetchAndMinifyJS(absoluteURL)
+	results := make([]string, len(tasks))
+	ok := make([]bool, len(tasks))
+
+	g := new(errgroup.Group)
+	sem := make(chan struct{}, workerPoolSize)
+	for i, task := range tasks {
+		i, task := i, task
+		g.Go(func() error {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			js, err := rp.fetchAndMinifyJS(task.url)
 			if err == nil {
-				// Replace external script with inline JavaScript
-				s.RemoveAttr("src")
-				s.SetText(js)
+				results[i] = js
+				ok[i] = true
 			}
+			return nil
+		})
+	}
+	g.Wait()
+
+	for i, task := range tasks {
+		if ok[i] {
+			task.sel.RemoveAttr("src")
+			task.sel.SetText(results[i])
 		}
-	})
+	}
 
 	// Process inline JavaScript
-	doc.Find("script:not([src])").Each(func(i int, s *qqquery.Selection) {
+	doc.Find("script:not([src])").Each(func(i int, s *goquery.Selection) {
 		js := s.Text()
 		// Skip if it contains YouTube player initialization
-		if strings.Contains(js, "youtube.com") || 
-		   strings.Contains(js, "YT.Player") {
+		if strings.Contains(js, "youtube.com") ||
+			strings.Contains(js, "YT.Player") {
 			return
 		}
 		minified, err := rp.minifier.String("application/javascript", js)
@@ -173,7 +400,12 @@ func (rp *ResourceProcessor) processJS(doc *goquery.Document) error {
 func (rp *ResourceProcessor) processImages(doc *goquery.Document) {
 	doc.Find("img[src]").Each(func(i int, s *goquery.Selection) {
 		if src, exists := s.Attr("src"); exists {
-			s.SetAttr("src", rp.makeAbsoluteURL(src))
+			s.SetAttr("src", rp.rewriter.ProxyURL(src))
+		}
+	})
+	doc.Find("img[srcset]").Each(func(i int, s *goquery.Selection) {
+		if srcset, exists := s.Attr("srcset"); exists {
+			s.SetAttr("srcset", rp.rewriter.RewriteSrcset(srcset))
 		}
 	})
 }
@@ -188,7 +420,7 @@ func (rp *ResourceProcessor) processIframes(doc *goquery.Document) {
 				s.SetAttr("allow", "accelerometer; autoplay; clipboard-write; encrypted-media; gyroscope; picture-in-picture")
 				return
 			}
-			s.SetAttr("src", rp.makeAbsoluteURL(src))
+			s.SetAttr("src", rp.rewriter.ProxyURL(src))
 		}
 	})
 }
@@ -201,51 +433,78 @@ func (rp *ResourceProcessor) makeAbsoluteURL(resourceURL string) string {
 }
 
 func (rp *ResourceProcessor) fetchAndMinifyCSS(url string) (string, error) {
-	resp, err := http.Get(url)
+	entry, err := rp.fetchResource(url)
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
 
-	content, err := io.ReadAll(resp.Body)
+	minified, err := rp.minifier.String("text/css", string(entry.Body))
 	if err != nil {
-		return "", err
-	}
-
-	minified, err := rp.minifier.String("text/css", string(content))
-	if err != nil {
-		return string(content), nil // Return original if minification fails
+		return string(entry.Body), nil // Return original if minification fails
 	}
 	return minified, nil
 }
 
 func (rp *ResourceProcessor) fetchAndMinifyJS(url string) (string, error) {
-	resp, err := http.Get(url)
-	if err != nil {
-		return "", err
-	}
-	defer resp.Body.Close()
-
-	content, err := io.ReadAll(resp.Body)
+	entry, err := rp.fetchResource(url)
 	if err != nil {
 		return "", err
 	}
 
-	minified, err := rp.minifier.String("application/javascript", string(content))
+	minified, err := rp.minifier.String("application/javascript", string(entry.Body))
 	if err != nil {
-		return string(content), nil // Return original if minification fails
+		return string(entry.Body), nil // Return original if minification fails
 	}
 	return minified, nil
 }
 
-// Add these new helper functions
-func extractVideoID(url string) string {
-	if strings.Contains(url, "youtube.com/watch?v=") {
-		return strings.Split(strings.Split(url, "watch?v=")[1], "&")[0]
-	} else if strings.Contains(url, "youtu.be/") {
-		return strings.Split(url, "youtu.be/")[1]
+// fetchResource fetches url through resourceCache with a timeout distinct
+// from (and shorter than) the overall page-request timeout, retrying with
+// backoff up to maxRetry times on network errors or a non-2xx status, and
+// recording each attempt's timing in DebugStats.RequestLog so /debug/stats
+// shows where time is spent. Unlike resourceCache.Fetch itself, a non-2xx
+// response here is treated as a failure worth retrying -- this is only used
+// for CSS/JS resource fetches, which have nothing useful to do with a
+// custom error page, unlike the page-level "/" and "/reader" handlers.
+func (rp *ResourceProcessor) fetchResource(url string) (*cache.Entry, error) {
+	var lastErr error
+	for attempt := 0; attempt <= maxRetry; attempt++ {
+		start := time.Now()
+		ctx, cancel := context.WithTimeout(context.Background(), fetchTimeout)
+		entry, err := resourceCache.FetchContext(ctx, url)
+		cancel()
+
+		status := 0
+		var size int64
+		if err == nil {
+			status = entry.StatusCode
+			size = int64(len(entry.Body))
+		}
+		stats.logRequest(RequestLogEntry{
+			Timestamp: start,
+			URL:       url,
+			Duration:  time.Since(start),
+			Status:    status,
+			Size:      size,
+		})
+
+		if err == nil && status >= 200 && status < 300 {
+			return entry, nil
+		}
+		if err != nil {
+			lastErr = err
+		} else {
+			lastErr = &cache.StatusError{URL: url, StatusCode: status}
+		}
+		if attempt < maxRetry {
+			time.Sleep(retryBackoff(attempt))
+		}
 	}
-	return ""
+	return nil, lastErr
+}
+
+func retryBackoff(attempt int) time.Duration {
+	return time.Duration(100*(1<<uint(attempt))) * time.Millisecond
 }
 
 func (rp *ResourceProcessor) processMeta(doc *goquery.Document) {
@@ -259,10 +518,28 @@ func (rp *ResourceProcessor) processMeta(doc *goquery.Document) {
 
 	// Handle CSP
 	doc.Find("meta[http-equiv='Content-Security-Policy']").Remove()
+
+	// Rewrite <meta http-equiv="refresh" content="N;url=..."> targets,
+	// JSON-LD @id/url fields, and any absolute URLs the origin embedded in
+	// inline style="" attributes, so none of those surfaces can escape the
+	// proxy.
+	rp.rewriter.RewriteMetaRefresh(doc)
+	rp.rewriter.RewriteJSONLD(doc)
+	doc.Find("[style]").Each(func(i int, s *goquery.Selection) {
+		if style, exists := s.Attr("style"); exists {
+			s.SetAttr("style", rp.rewriter.RewriteCSS(style))
+		}
+	})
+
+	// Inject the client-side navigation shim before any other script runs,
+	// so fetch/XHR/Worker/WebSocket/history calls are proxied too.
+	doc.Find("head").PrependHtml(rewriter.Shim("/?url=", rp.rewriter.Nonce()))
 }
 
 func (rp *ResourceProcessor) processHead(doc *goquery.Document) error {
-	// Process base tag
+	// Process base tag. The base href itself must stay an absolute origin
+	// URL (not proxied) since it's only used to resolve other relative URLs
+	// before we rewrite them.
 	doc.Find("base").Each(func(i int, s *goquery.Selection) {
 		if href, exists := s.Attr("href"); exists {
 			s.SetAttr("href", rp.makeAbsoluteURL(href))
@@ -272,7 +549,7 @@ func (rp *ResourceProcessor) processHead(doc *goquery.Document) error {
 	// Process favicons
 	doc.Find("link[rel='icon'], link[rel='shortcut icon']").Each(func(i int, s *goquery.Selection) {
 		if href, exists := s.Attr("href"); exists {
-			s.SetAttr("href", rp.makeAbsoluteURL(href))
+			s.SetAttr("href", rp.rewriter.ProxyURL(href))
 		}
 	})
 
@@ -283,8 +560,75 @@ func main() {
 	// Add debug flag
 	debug := flag.Bool("debug", false, "Enable debug mode")
 	debugShort := flag.Bool("d", false, "Enable debug mode")
+	proxySecret := flag.String("proxy-secret", os.Getenv("PROXY_SECRET"), "HMAC secret used to sign proxied URLs; unset disables signing")
+	ytEmbedURL := flag.String("youtube-embed-url", envOrDefault("YOUTUBE_EMBED_URL", youtube.DefaultEmbedURLBase), "Base embed URL used for the iframe fallback when yt-dlp is unavailable (e.g. a self-hosted Invidious/Piped instance)")
+	cacheSize := flag.Int64("cache-size", 128<<20, "Max bytes held by the resource cache (in-memory or on-disk, whichever --cache-dir selects)")
+	cacheTTL := flag.Duration("cache-ttl", 5*time.Minute, "Cap on how long a cached resource is served without revalidation")
+	cacheDir := flag.String("cache-dir", "", "Directory for an on-disk BoltDB resource cache; empty uses an in-memory cache only")
+	poolSize := flag.Int("worker-pool-size", 8, "Max concurrent external CSS/JS fetches per page")
+	maxRetryFlag := flag.Int("max-retry", 2, "Max retries (with backoff) for a failed resource fetch")
+	fetchTimeoutFlag := flag.Duration("fetch-timeout", 5*time.Second, "Timeout for a single resource fetch, distinct from the overall page timeout")
+	cspImgSrc := flag.String("csp-img-src", "", "Override the CSP img-src directive; defaults to the proxy's own origin plus data: URIs")
+	cspFrameSrc := flag.String("csp-frame-src", "", "Override the CSP frame-src directive; defaults to the YouTube iframe fallback's origin")
+	cspConnectSrc := flag.String("csp-connect-src", "", "Override the CSP connect-src directive; defaults to 'self'")
+	cspMediaSrc := flag.String("csp-media-src", "", "Override the CSP media-src directive; defaults to 'self'")
+	cspObjectSrc := flag.String("csp-object-src", "", "Override the CSP object-src directive; defaults to 'self'")
+	cspPermissionsPolicy := flag.String("permissions-policy", "", "Override the Permissions-Policy header; defaults to disabling geolocation/microphone/camera")
 	flag.Parse()
 
+	youtubeEmbedURL = *ytEmbedURL
+	workerPoolSize = *poolSize
+	maxRetry = *maxRetryFlag
+	fetchTimeout = *fetchTimeoutFlag
+
+	cspDirectives := csp.DefaultDirectives()
+	if frameSrc, err := youtubeEmbedFrameSrc(youtubeEmbedURL); err == nil {
+		cspDirectives.FrameSrc = frameSrc
+	}
+	if *cspImgSrc != "" {
+		cspDirectives.ImgSrc = *cspImgSrc
+	}
+	if *cspFrameSrc != "" {
+		cspDirectives.FrameSrc = *cspFrameSrc
+	}
+	if *cspConnectSrc != "" {
+		cspDirectives.ConnectSrc = *cspConnectSrc
+	}
+	if *cspMediaSrc != "" {
+		cspDirectives.MediaSrc = *cspMediaSrc
+	}
+	if *cspObjectSrc != "" {
+		cspDirectives.ObjectSrc = *cspObjectSrc
+	}
+	if *cspPermissionsPolicy != "" {
+		cspDirectives.Permissions = *cspPermissionsPolicy
+	}
+
+	if *proxySecret != "" {
+		proxySigner = rewriter.NewSigner(*proxySecret)
+		log.Println("Proxy URL signing enabled")
+	}
+
+	var cacheStore cache.Store
+	if *cacheDir != "" {
+		store, err := cache.NewBoltStore(filepath.Join(*cacheDir, "resources.db"), *cacheSize)
+		if err != nil {
+			log.Fatalf("opening cache directory: %v", err)
+		}
+		cacheStore = store
+	} else {
+		cacheStore = cache.NewMemoryStore(*cacheSize)
+	}
+	resourceCache = cache.New(cacheStore, &http.Client{
+		Timeout: 10 * time.Second,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return fmt.Errorf("too many redirects")
+			}
+			return nil
+		},
+	}, *cacheTTL, decorateFetchRequest)
+
 	// Enable debug if either flag is set
 	stats.Enabled = *debug || *debugShort
 	if stats.Enabled {
@@ -304,13 +648,15 @@ func main() {
 		Views: engine,
 	})
 
+	app.Use(csp.Middleware(cspDirectives))
+
 	// Add debug middleware
 	app.Use(func(c *fiber.Ctx) error {
 		if stats.Enabled {
 			start := time.Now()
 			err := c.Next()
 			duration := time.Since(start)
-			
+
 			entry := RequestLogEntry{
 				Timestamp: start,
 				URL:       c.OriginalURL(),
@@ -338,79 +684,87 @@ func main() {
 			})
 		}
 
+		if proxySigner != nil {
+			sig := c.Query("sig")
+			if sig == "" {
+				if !sameOriginReferer(c) {
+					return c.Status(fiber.StatusForbidden).Render("index", fiber.Map{
+						"Error": "Refusing to fetch an unsigned or tampered proxy URL",
+					})
+				}
+				return c.Redirect(signedRedirect("/", url))
+			}
+			if !proxySigner.Verify(url, sig) {
+				return c.Status(fiber.StatusForbidden).Render("index", fiber.Map{
+					"Error": "Refusing to fetch an unsigned or tampered proxy URL",
+				})
+			}
+		}
+
 		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
 			url = "http://" + url
 		}
 
-		// Special handling for YouTube URLs
-		if strings.Contains(url, "youtube.com") || strings.Contains(url, "youtu.be") {
-			videoID := extractVideoID(url)
+		// Special handling for YouTube URLs: extract real media sources via
+		// yt-dlp so we can render a native <video> element, falling back to
+		// the iframe embed if yt-dlp isn't installed or extraction fails.
+		if youtube.Match(url) {
+			videoID := youtube.VideoID(url)
 			if videoID != "" {
+				content := youtubeIframeFallback(videoID)
+				if youtube.Available() {
+					ctx, cancel := context.WithTimeout(c.Context(), 15*time.Second)
+					video, err := youtube.Extract(ctx, url)
+					cancel()
+					if err == nil && len(video.Sources()) > 0 {
+						var rwOpts []rewriter.Option
+						if proxySigner != nil {
+							rwOpts = append(rwOpts, rewriter.WithSigner(proxySigner))
+						}
+						content = youtube.RenderVideo(video, rewriter.New(url, rwOpts...).ProxyURL)
+					}
+				}
 				return c.Render("index", fiber.Map{
 					"CurrentURL": url,
-					"Content": template.HTML(`
-						<div class="video-container">
-							<iframe 
-								src="https://www.youtube.com/embed/` + videoID + `" 
-								frameborder="0" 
-								allowfullscreen="true"
-								allow="accelerometer; autoplay; clipboard-write; encrypted-media; gyroscope; picture-in-picture">
-							</iframe>
-						</div>
-					`),
+					"Content":    template.HTML(content),
 				})
 			}
 		}
 
-		client := &http.Client{
-			Timeout: 10 * time.Second,
-			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				if len(via) >= 10 {
-					return fmt.Errorf("too many redirects")
-				}
-				return nil
-			},
-		}
-
-		// Add headers to bypass some restrictions
-		req, err := http.NewRequest("GET", url, nil)
-		if err != nil {
-			return c.Render("index", fiber.Map{
-				"Error": fmt.Sprintf("Error creating request: %v", err),
-			})
+		// A byte-range request (native audio/video seeking) is proxied
+		// straight to the origin instead of going through resourceCache,
+		// which can't serve partial content out of its fully-buffered
+		// entries.
+		if rangeHeader := c.Get("Range"); rangeHeader != "" {
+			return proxyRange(c, url, rangeHeader)
 		}
-		req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/91.0.4472.124 Safari/537.36")
-		req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/webp,*/*;q=0.8")
-		req.Header.Set("Accept-Language", "en-US,en;q=0.5")
-		req.Header.Set("Sec-Fetch-Dest", "document")
-		req.Header.Set("Sec-Fetch-Mode", "navigate")
-		req.Header.Set("Sec-Fetch-Site", "none")
-		req.Header.Set("Sec-Fetch-User", "?1")
-		req.Header.Set("Upgrade-Insecure-Requests", "1")
 
-		resp, err := client.Do(req)
+		// Fetches go through resourceCache, which revalidates with
+		// If-None-Match/If-Modified-Since and coalesces concurrent
+		// requests for the same URL instead of hitting the origin on
+		// every request.
+		entry, err := resourceCache.Fetch(url)
 		if err != nil {
 			return c.Render("index", fiber.Map{
 				"Error": fmt.Sprintf("Error fetching page: %v", err),
 			})
 		}
-		defer resp.Body.Close()
 
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			return c.Render("index", fiber.Map{
-				"Error": fmt.Sprintf("Error reading response: %v", err),
-			})
+		// Dispatch non-HTML responses (images, audio, video, PDFs, source
+		// code) to a registered content.Handler instead of forcing them
+		// through the HTML rewriting pipeline.
+		if h := content.Lookup(entry.ContentType, url); h != nil {
+			return h.Serve(c, entryToResponse(entry), url)
 		}
 
-		doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(body)))
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(entry.Body)))
 		if err != nil {
 			return c.Render("index", fiber.Map{
 				"Error": fmt.Sprintf("Error parsing HTML: %v", err),
 			})
 		}
 
-		processor := NewResourceProcessor(url)
+		processor := NewResourceProcessor(url, csp.NonceFrom(c))
 
 		// Process everything
 		processor.processMeta(doc)
@@ -419,33 +773,20 @@ func main() {
 		processor.processJS(doc)
 		processor.processImages(doc)
 		processor.processIframes(doc)
+		processor.rewriter.RewriteMediaAttrs(doc)
+		processor.rewriter.StampNonces(doc)
 
 		// Process forms
 		doc.Find("form").Each(func(i int, s *goquery.Selection) {
 			if action, exists := s.Attr("action"); exists {
-				if !strings.HasPrefix(action, "http") {
-					absoluteURL := processor.makeAbsoluteURL(action)
-					s.SetAttr("action", fmt.Sprintf("/?url=%s", absoluteURL))
-				} else {
-					s.SetAttr("action", fmt.Sprintf("/?url=%s", action))
-				}
+				s.SetAttr("action", processor.rewriter.ProxyURL(action))
 			}
 		})
 
 		// Process links
 		doc.Find("a[href]").Each(func(i int, s *goquery.Selection) {
-			href, exists := s.Attr("href")
-			if exists {
-				// Skip javascript: links and anchors
-				if strings.HasPrefix(href, "javascript:") || strings.HasPrefix(href, "#") {
-					return
-				}
-				if !strings.HasPrefix(href, "http") {
-					absoluteURL := processor.makeAbsoluteURL(href)
-					s.SetAttr("href", fmt.Sprintf("/?url=%s", absoluteURL))
-				} else {
-					s.SetAttr("href", fmt.Sprintf("/?url=%s", href))
-				}
+			if href, exists := s.Attr("href"); exists {
+				s.SetAttr("href", processor.rewriter.ProxyURL(href))
 			}
 		})
 
@@ -468,6 +809,61 @@ func main() {
 		})
 	})
 
+	// Distraction-free reader view: extract the main article content and
+	// sanitize it instead of rewriting the page's own scripts/styles.
+	app.Get("/reader", func(c *fiber.Ctx) error {
+		url := c.Query("url")
+		if url == "" {
+			return c.Status(fiber.StatusBadRequest).SendString("missing ?url=")
+		}
+		if proxySigner != nil {
+			sig := c.Query("sig")
+			if sig == "" {
+				if !sameOriginReferer(c) {
+					return c.Status(fiber.StatusForbidden).SendString("unsigned or tampered proxy URL")
+				}
+				return c.Redirect(signedRedirect("/reader", url))
+			}
+			if !proxySigner.Verify(url, sig) {
+				return c.Status(fiber.StatusForbidden).SendString("unsigned or tampered proxy URL")
+			}
+		}
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			url = "http://" + url
+		}
+
+		entry, err := resourceCache.Fetch(url)
+		if err != nil {
+			return c.Status(fiber.StatusBadGateway).SendString(fmt.Sprintf("Error fetching page: %v", err))
+		}
+
+		doc, err := goquery.NewDocumentFromReader(strings.NewReader(string(entry.Body)))
+		if err != nil {
+			return c.Status(fiber.StatusUnprocessableEntity).SendString(fmt.Sprintf("Error parsing HTML: %v", err))
+		}
+
+		var rwOpts []rewriter.Option
+		if proxySigner != nil {
+			rwOpts = append(rwOpts, rewriter.WithSigner(proxySigner))
+		}
+		if nonce := csp.NonceFrom(c); nonce != "" {
+			rwOpts = append(rwOpts, rewriter.WithNonce(nonce))
+		}
+		article, err := reader.Extract(doc, rewriter.New(url, rwOpts...))
+		if err != nil {
+			return c.Status(fiber.StatusUnprocessableEntity).SendString(fmt.Sprintf("Error extracting article: %v", err))
+		}
+
+		return c.Render("reader", fiber.Map{
+			"Title":          article.Title,
+			"Byline":         article.Byline,
+			"PublishDate":    article.PublishDate,
+			"ReadingMinutes": article.ReadingMinutes,
+			"Content":        template.HTML(article.Content),
+			"Nonce":          csp.NonceFrom(c),
+		})
+	})
+
 	// Proxy route for YouTube API calls
 	app.Get("/yt/*", func(c *fiber.Ctx) error {
 		url := "https://www.youtube.com/" + c.Params("*")
@@ -491,14 +887,17 @@ func main() {
 		app.Get("/debug/stats", func(c *fiber.Ctx) error {
 			stats.Lock()
 			defer stats.Unlock()
-			
+
+			cacheStats := resourceCache.Stats()
 			return c.JSON(fiber.Map{
-				"uptime":          time.Since(stats.StartTime).String(),
-				"requestCount":    stats.RequestCount,
-				"bytesProcessed":  stats.BytesProcessed,
-				"lastRequests":    stats.RequestLog[max(0, len(stats.RequestLog)-10):],
-				"goroutines":      runtime.NumGoroutine(),
-				"memoryUsageMB":   float64(runtime.MemStats{}.Alloc) / 1024 / 1024,
+				"uptime":         time.Since(stats.StartTime).String(),
+				"requestCount":   stats.RequestCount,
+				"bytesProcessed": stats.BytesProcessed,
+				"lastRequests":   stats.RequestLog[max(0, len(stats.RequestLog)-10):],
+				"goroutines":     runtime.NumGoroutine(),
+				"memoryUsageMB":  float64(runtime.MemStats{}.Alloc) / 1024 / 1024,
+				"cacheHits":      cacheStats.Hits,
+				"cacheMisses":    cacheStats.Misses,
 			})
 		})
 	}
@@ -512,4 +911,22 @@ func max(a, b int) int {
 		return a
 	}
 	return b
-} 
\ No newline at end of file
+}
+
+func envOrDefault(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
+
+// youtubeEmbedFrameSrc returns the origin of embedURL, which is what the CSP
+// frame-src directive needs to allow so the YouTube iframe fallback isn't
+// blocked by the proxy's own policy.
+func youtubeEmbedFrameSrc(embedURL string) (string, error) {
+	parsed, err := neturl.Parse(embedURL)
+	if err != nil {
+		return "", err
+	}
+	return parsed.Scheme + "://" + parsed.Host, nil
+}